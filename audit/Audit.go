@@ -0,0 +1,41 @@
+// Package audit persists security-relevant events — login success and
+// failure, token issuance and revocation, triggered publishes — to the
+// audit_log table so operators have post-hoc forensics that the structured
+// request log alone can't provide. Role changes and server command
+// executions are not recorded.
+package audit
+
+import (
+	"time"
+
+	"github.com/zhenorzz/goploy/model"
+)
+
+// Action identifies the kind of security-relevant event being recorded.
+type Action string
+
+// ActionRoleChanged and ActionServerCommandExecuted were dropped: this
+// snapshot has no role-change endpoint and no server command execution
+// pipeline, so neither action could ever actually be recorded. Re-add them
+// alongside the endpoint/pipeline that makes them reachable.
+const (
+	ActionLoginSuccess     Action = "login.success"
+	ActionLoginFailure     Action = "login.failure"
+	ActionTokenIssued      Action = "token.issued"
+	ActionTokenRevoked     Action = "token.revoked"
+	ActionPublishTriggered Action = "publish.triggered"
+)
+
+// Record persists one audit event, using requestID to correlate it with
+// the structured request log line that produced it.
+func Record(requestID string, userID int64, namespaceID int64, action Action, detail string) error {
+	_, err := model.AuditLog{
+		RequestID:   requestID,
+		UserID:      userID,
+		NamespaceID: namespaceID,
+		Action:      string(action),
+		Detail:      detail,
+		CreatedAt:   time.Now().Unix(),
+	}.AddRow()
+	return err
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/zhenorzz/goploy/core"
+	"github.com/zhenorzz/goploy/model"
+	"github.com/zhenorzz/goploy/response"
+)
+
+// Audit implements core.RouteApi for the read-only audit trail endpoint.
+type Audit struct{}
+
+func (Audit) Routes() []core.Route {
+	return []core.Route{
+		core.NewRoute("/api/audit/list", http.MethodGet, list).Roles("admin"),
+	}
+}
+
+func list(gp *core.Goploy) core.Response {
+	page, _ := strconv.ParseInt(gp.URLQuery.Get("page"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.ParseInt(gp.URLQuery.Get("limit"), 10, 64)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	logs, total, err := model.AuditLog{}.GetListByPage(page, limit)
+	if err != nil {
+		return response.JSON{Code: response.Error, Message: err.Error()}
+	}
+
+	return response.JSON{Data: struct {
+		Total int64           `json:"total"`
+		List  model.AuditLogs `json:"list"`
+	}{Total: total, List: logs}}
+}
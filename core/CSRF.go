@@ -0,0 +1,86 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/zhenorzz/goploy/config"
+	"github.com/zhenorzz/goploy/response"
+)
+
+// CSRFCookieName is the double-submit cookie CSRF issues on safe requests
+// and requires echoed back in CSRFHeaderName on unsafe ones.
+const CSRFCookieName = "goploy_csrf"
+
+// CSRFHeaderName is the header a non-safe request must set to the current
+// CSRFCookieName value to pass the guard.
+const CSRFHeaderName = "X-CSRF-Token"
+
+var csrfSafeMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+}
+
+// CSRF is a double-submit cookie guard against state-changing requests
+// authenticated purely by the goploy session cookie. It issues a
+// SameSite=Lax, non-HttpOnly goploy_csrf cookie on safe methods, and
+// requires a matching X-CSRF-Token header on any other method, except for
+// white routes, routes that opted out with Route.SkipCSRF (e.g. webhook
+// receivers already authenticated by a provider signature), and requests
+// authenticated via the chunk0-2 Bearer Personal Access Token path: those
+// clients (CI runners, curl scripts, webhook senders) never receive the
+// goploy_csrf cookie, and a bearer token isn't auto-attached by a browser
+// so it can't be forged by a third-party site in the first place.
+func CSRF(gp *Goploy) error {
+	if !config.Toml.Security.CSRF.Enabled || gp.White || gp.CSRFExempt || gp.BearerAuthenticated {
+		return nil
+	}
+
+	if _, safe := csrfSafeMethods[gp.Request.Method]; safe {
+		issueCSRFCookie(gp)
+		return nil
+	}
+
+	cookie, err := gp.Request.Cookie(CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return csrfRejected(gp)
+	}
+
+	if !csrfTokensMatch(gp.Request.Header.Get(CSRFHeaderName), cookie.Value) {
+		return csrfRejected(gp)
+	}
+
+	return nil
+}
+
+// csrfTokensMatch reports whether the X-CSRF-Token header matches the
+// goploy_csrf cookie value, in constant time.
+func csrfTokensMatch(header, cookie string) bool {
+	return header != "" && subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) == 1
+}
+
+func issueCSRFCookie(gp *Goploy) {
+	if existing, err := gp.Request.Cookie(CSRFCookieName); err == nil && existing.Value != "" {
+		return
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	http.SetCookie(gp.ResponseWriter, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    hex.EncodeToString(b),
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+		HttpOnly: false,
+	})
+}
+
+func csrfRejected(gp *Goploy) error {
+	gp.ResponseWriter.Header().Set("Content-Type", "application/json")
+	gp.ResponseWriter.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(gp.ResponseWriter).Encode(response.JSON{Code: response.Deny, Message: "Missing or invalid CSRF token"})
+	return ErrHandled
+}
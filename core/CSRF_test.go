@@ -0,0 +1,26 @@
+package core
+
+import "testing"
+
+func TestCSRFTokensMatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		cookie string
+		want   bool
+	}{
+		{"matching", "abc123", "abc123", true},
+		{"mismatched", "abc123", "def456", false},
+		{"empty header", "", "abc123", false},
+		{"empty cookie", "abc123", "", false},
+		{"both empty", "", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := csrfTokensMatch(c.header, c.cookie); got != c.want {
+				t.Errorf("csrfTokensMatch(%q, %q) = %v, want %v", c.header, c.cookie, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhenorzz/goploy/config"
+	"github.com/zhenorzz/goploy/response"
+)
+
+// limiter is the backend a RateLimit middleware consumes from. memoryLimiter
+// and redisLimiter both satisfy it so config.Toml.RateLimit.Driver can pick
+// one without the middleware caring which.
+type limiter interface {
+	// Allow reports whether key may proceed, how long it must wait to
+	// retry if not, and how many requests remain in the current window.
+	Allow(key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, remaining int)
+}
+
+// RateLimitOptions configures one RateLimit middleware instance.
+type RateLimitOptions struct {
+	Rate    float64                 // sustained requests per second
+	Burst   int                     // max requests allowed in a single burst
+	KeyFunc func(gp *Goploy) string // defaults to per-user, falling back to per-IP
+}
+
+func defaultKeyFunc(gp *Goploy) string {
+	if gp.UserInfo.ID != 0 {
+		return "user:" + strconv.FormatInt(gp.UserInfo.ID, 10)
+	}
+	return "ip:" + remoteIP(gp.Request)
+}
+
+func remoteIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host := r.RemoteAddr
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	return host
+}
+
+var defaultLimiter limiter
+
+// rateLimitBackend lazily builds the limiter selected by
+// config.Toml.RateLimit.Driver, defaulting to the in-memory token bucket.
+func rateLimitBackend() limiter {
+	if defaultLimiter != nil {
+		return defaultLimiter
+	}
+	if config.Toml.RateLimit.Driver == "redis" {
+		defaultLimiter = newRedisLimiter()
+	} else {
+		defaultLimiter = newMemoryLimiter()
+	}
+	return defaultLimiter
+}
+
+// defaultRate and defaultBurst backstop a RateLimitOptions (or
+// config.Toml.RateLimit) that leaves Rate/Burst unset. Without them a zero
+// rate divides by zero computing Retry-After, and a zero burst starts every
+// bucket at 0 tokens, 429-ing every request out of the box.
+const (
+	defaultRate  = 5.0
+	defaultBurst = 10
+)
+
+// RateLimit returns a middleware enforcing a token-bucket limit of opts.Rate
+// requests/sec with burst capacity opts.Burst, keyed by opts.KeyFunc (or the
+// default per-user/per-IP key). Attach it via Router.Middleware for a global
+// limit, or Route.Middleware for a tighter per-route override, e.g. on
+// /api/user/login to blunt brute force.
+func RateLimit(opts RateLimitOptions) func(gp *Goploy) error {
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = defaultKeyFunc
+	}
+	rate := opts.Rate
+	if rate == 0 {
+		rate = config.Toml.RateLimit.Rate
+	}
+	if rate == 0 {
+		rate = defaultRate
+	}
+	burst := opts.Burst
+	if burst == 0 {
+		burst = config.Toml.RateLimit.Burst
+	}
+	if burst == 0 {
+		burst = defaultBurst
+	}
+
+	// Every RateLimit shares one backend, so buckets are namespaced by
+	// rate/burst: a per-route override must not consume from (and be
+	// refilled through) the global limiter's bucket for the same user/IP.
+	bucketPrefix := fmt.Sprintf("%g/%d:", rate, burst)
+
+	return func(gp *Goploy) error {
+		key := bucketPrefix + keyFunc(gp)
+		allowed, retryAfter, remaining := rateLimitBackend().Allow(key, rate, burst)
+
+		gp.ResponseWriter.Header().Set("X-RateLimit-Limit", strconv.Itoa(burst))
+		gp.ResponseWriter.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			gp.ResponseWriter.Header().Set("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()))
+			gp.ResponseWriter.Header().Set("Content-Type", "application/json")
+			gp.ResponseWriter.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(gp.ResponseWriter).Encode(response.JSON{Code: response.Deny, Message: "Too many requests"})
+			return ErrHandled
+		}
+		return nil
+	}
+}
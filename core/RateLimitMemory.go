@@ -0,0 +1,94 @@
+package core
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const rateLimitShardCount = 32
+
+// rateLimitIdleTTL is how long a bucket may sit untouched before the janitor evicts it.
+const rateLimitIdleTTL = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+type rateLimitShard struct {
+	mu      sync.RWMutex
+	buckets map[string]*tokenBucket
+}
+
+// memoryLimiter is a sharded, in-memory token bucket limiter. Sharding keeps
+// lock contention low under concurrent requests from many distinct keys.
+type memoryLimiter struct {
+	shards [rateLimitShardCount]*rateLimitShard
+}
+
+func newMemoryLimiter() *memoryLimiter {
+	m := &memoryLimiter{}
+	for i := range m.shards {
+		m.shards[i] = &rateLimitShard{buckets: map[string]*tokenBucket{}}
+	}
+	go m.janitor()
+	return m
+}
+
+func (m *memoryLimiter) shardFor(key string) *rateLimitShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return m.shards[h.Sum32()%rateLimitShardCount]
+}
+
+func (m *memoryLimiter) Allow(key string, rate float64, burst int) (bool, time.Duration, int) {
+	shard := m.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), lastRefill: now}
+		shard.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = rateLimitMin(float64(burst), b.tokens+elapsed*rate)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, retryAfter, 0
+	}
+
+	b.tokens--
+	return true, 0, int(b.tokens)
+}
+
+// janitor evicts buckets that have gone untouched for rateLimitIdleTTL so
+// the map doesn't grow unbounded with one-off callers.
+func (m *memoryLimiter) janitor() {
+	ticker := time.NewTicker(rateLimitIdleTTL)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		for _, shard := range m.shards {
+			shard.mu.Lock()
+			for key, b := range shard.buckets {
+				if now.Sub(b.lastRefill) > rateLimitIdleTTL {
+					delete(shard.buckets, key)
+				}
+			}
+			shard.mu.Unlock()
+		}
+	}
+}
+
+func rateLimitMin(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterBurstThenExhausted(t *testing.T) {
+	m := newMemoryLimiter()
+
+	for i := 0; i < 3; i++ {
+		allowed, _, _ := m.Allow("k", 1, 3)
+		if !allowed {
+			t.Fatalf("request %d within burst should be allowed", i)
+		}
+	}
+
+	allowed, retryAfter, remaining := m.Allow("k", 1, 3)
+	if allowed {
+		t.Fatalf("request beyond burst should be rejected")
+	}
+	if remaining != 0 {
+		t.Errorf("remaining = %d, want 0", remaining)
+	}
+	if retryAfter <= 0 {
+		t.Errorf("retryAfter = %v, want > 0", retryAfter)
+	}
+}
+
+func TestMemoryLimiterRefillsOverTime(t *testing.T) {
+	m := newMemoryLimiter()
+	shard := m.shardFor("k")
+
+	// Exhaust the bucket, then rewind lastRefill to simulate elapsed time
+	// without sleeping the test.
+	allowed, _, _ := m.Allow("k", 10, 1)
+	if !allowed {
+		t.Fatalf("first request should be allowed")
+	}
+	shard.mu.Lock()
+	shard.buckets["k"].lastRefill = shard.buckets["k"].lastRefill.Add(-1 * time.Second)
+	shard.mu.Unlock()
+
+	allowed, _, _ = m.Allow("k", 10, 1)
+	if !allowed {
+		t.Errorf("request after refill window should be allowed again")
+	}
+}
+
+func TestMemoryLimiterKeysAreIndependent(t *testing.T) {
+	m := newMemoryLimiter()
+
+	if allowed, _, _ := m.Allow("a", 1, 1); !allowed {
+		t.Fatalf("first request for key a should be allowed")
+	}
+	if allowed, _, _ := m.Allow("a", 1, 1); allowed {
+		t.Fatalf("second immediate request for key a should be rejected")
+	}
+	if allowed, _, _ := m.Allow("b", 1, 1); !allowed {
+		t.Errorf("key b should have its own bucket, unaffected by key a")
+	}
+}
+
+func TestRateLimitMin(t *testing.T) {
+	if got := rateLimitMin(2, 5); got != 2 {
+		t.Errorf("rateLimitMin(2, 5) = %v, want 2", got)
+	}
+	if got := rateLimitMin(5, 2); got != 2 {
+		t.Errorf("rateLimitMin(5, 2) = %v, want 2", got)
+	}
+}
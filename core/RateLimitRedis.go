@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zhenorzz/goploy/config"
+)
+
+// redisLimiter is a fixed-window counter shared across all goploy instances,
+// used instead of memoryLimiter when multiple server processes sit behind a
+// load balancer and need a consistent view of each key's usage.
+type redisLimiter struct {
+	client *redis.Client
+	window time.Duration
+}
+
+func newRedisLimiter() *redisLimiter {
+	return &redisLimiter{
+		client: redis.NewClient(&redis.Options{Addr: config.Toml.RateLimit.RedisAddr}),
+		window: time.Second,
+	}
+}
+
+func (rl *redisLimiter) Allow(key string, rate float64, burst int) (bool, time.Duration, int) {
+	ctx := context.Background()
+	redisKey := "goploy:ratelimit:" + key
+
+	// Size the fixed window so that admitting burst requests over it
+	// averages out to the configured sustained rate, instead of ignoring
+	// rate and allowing burst requests every fixed second regardless of
+	// how low rate is.
+	window := rl.window
+	if rate > 0 {
+		window = time.Duration(float64(burst) / rate * float64(time.Second))
+	}
+
+	count, err := rl.client.Incr(ctx, redisKey).Result()
+	if err != nil {
+		// Fail open: a Redis outage shouldn't take the whole API down.
+		return true, 0, burst
+	}
+	if count == 1 {
+		rl.client.Expire(ctx, redisKey, window)
+	}
+
+	if int(count) > burst {
+		ttl, _ := rl.client.TTL(ctx, redisKey).Result()
+		return false, ttl, 0
+	}
+
+	return true, 0, burst - int(count)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+type requestIDContextKey struct{}
+
+// newRequestID generates a random, URL-safe request correlation id. A
+// simple random hex string is good enough here since it only needs to be
+// unique, not sortable or parseable.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusResponseWriter records the status code and byte count a handler
+// wrote so ServeHTTP can log them after the fact.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Hijack delegates to the embedded ResponseWriter so WSRoute's
+// websocket.Upgrade (which type-asserts for http.Hijacker) keeps working
+// through this wrapper.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("core: underlying ResponseWriter does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// requestLogLine is the structured JSON line emitted once per request.
+type requestLogLine struct {
+	RequestID   string `json:"requestId"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Status      int    `json:"status"`
+	Bytes       int    `json:"bytes"`
+	DurationMs  int64  `json:"durationMs"`
+	UserID      int64  `json:"userId,omitempty"`
+	NamespaceID int64  `json:"namespaceId,omitempty"`
+	RemoteIP    string `json:"remoteIp"`
+	UserAgent   string `json:"userAgent"`
+	Error       string `json:"error,omitempty"`
+}
+
+// logRequest emits a single structured JSON line describing the request
+// that just finished, replacing the old ad-hoc Log(ERROR, ...) call.
+func logRequest(gp *Goploy, r *http.Request, w *statusResponseWriter, requestID string, duration time.Duration, err error) {
+	line := requestLogLine{
+		RequestID:  requestID,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Status:     w.status,
+		Bytes:      w.bytes,
+		DurationMs: duration.Milliseconds(),
+		RemoteIP:   remoteIP(r),
+		UserAgent:  r.UserAgent(),
+	}
+	if gp != nil {
+		line.UserID = gp.UserInfo.ID
+		line.NamespaceID = gp.Namespace.ID
+	}
+	if err != nil {
+		line.Error = err.Error()
+	}
+
+	out, marshalErr := json.Marshal(line)
+	if marshalErr != nil {
+		Log(ERROR, marshalErr.Error())
+		return
+	}
+	log.Println(string(out))
+}
@@ -1,10 +1,12 @@
 package core
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt"
+	"github.com/zhenorzz/goploy/audit"
 	"github.com/zhenorzz/goploy/config"
 	"github.com/zhenorzz/goploy/model"
 	"github.com/zhenorzz/goploy/response"
@@ -17,16 +19,22 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Goploy callback param
 type Goploy struct {
-	UserInfo       model.User
-	Namespace      model.Namespace
-	Request        *http.Request
-	ResponseWriter http.ResponseWriter
-	URLQuery       url.Values
-	Body           []byte
+	UserInfo            model.User
+	Namespace           model.Namespace
+	Request             *http.Request
+	ResponseWriter      http.ResponseWriter
+	URLQuery            url.Values
+	Body                []byte
+	RequestID           string // correlates this request's log line with any audit.Record it triggers
+	Context             context.Context
+	White               bool // true if the matched Route skips login
+	CSRFExempt          bool // true if the matched Route opted out of the CSRF guard
+	BearerAuthenticated bool // true if auth came from a PAT Authorization header rather than the goploy cookie
 }
 
 type RouteApi interface {
@@ -37,13 +45,38 @@ type Response interface {
 	Write(http.ResponseWriter) error
 }
 
+// ResponseError is optionally implemented by a Response to expose the
+// business failure it represents (a deny, validation error, expired login,
+// ...) for logging. Write succeeding just means the JSON body describing
+// that failure made it to the wire — it says nothing about whether the
+// request itself succeeded, so logRequest falls back to this instead of
+// logging every deny as a clean, error-less 200. response.JSON should
+// implement it, returning Message when Code indicates failure.
+type ResponseError interface {
+	ResponseError() string
+}
+
+// ErrHandled is returned by a middleware that has already written its own
+// status code and body to gp.ResponseWriter (e.g. RateLimit's 429), telling
+// doRequest not to write a second, generic error response on top of it.
+var ErrHandled = errors.New("core: response already written")
+
+// noResponse satisfies Response for middlewares that used ErrHandled.
+type noResponse struct{}
+
+func (noResponse) Write(http.ResponseWriter) error {
+	return nil
+}
+
 type Route struct {
 	pattern     string                    //
 	method      string                    // Method specifies the HTTP method (GET, POST, PUT, etc.).
 	roles       map[string]struct{}       // permission role
+	scopes      map[string]struct{}       // personal access token scopes accepted by this route
 	callback    func(gp *Goploy) Response // Controller function
 	middlewares []func(gp *Goploy) error  // Middlewares run before all callback
 	white       bool                      // no need to login
+	csrfExempt  bool                      // skip the CSRF guard, e.g. webhook receivers authenticated by provider signature
 }
 
 // Router is Route slice and global middlewares
@@ -54,7 +87,8 @@ type Router struct {
 
 func NewRouter() Router {
 	return Router{
-		routes: map[string]Route{},
+		routes:      map[string]Route{},
+		middlewares: []func(gp *Goploy) error{SecurityHeaders, CSRF, RateLimit(RateLimitOptions{})},
 	}
 }
 
@@ -64,11 +98,13 @@ func NewRoute(pattern, method string, callback func(gp *Goploy) Response) Route
 		method:   method,
 		callback: callback,
 		roles:    map[string]struct{}{},
+		scopes:   map[string]struct{}{},
 	}
 }
 
 // Start a router
 func (rt Router) Start() {
+	go Hub.Run()
 	if config.Toml.Env == "production" {
 		subFS, err := fs.Sub(web.Dist, "dist")
 		if err != nil {
@@ -108,6 +144,22 @@ func (r Route) Roles(roles ...string) Route {
 	return r
 }
 
+// SkipCSRF exempts the Route from the double-submit CSRF guard, for
+// endpoints such as webhook receivers that are already authenticated by a
+// provider signature instead of the goploy cookie.
+func (r Route) SkipCSRF() Route {
+	r.csrfExempt = true
+	return r
+}
+
+// Scopes Add personal access token scopes accepted by the Route
+func (r Route) Scopes(scopes ...string) Route {
+	for _, scope := range scopes {
+		r.scopes[scope] = struct{}{}
+	}
+	return r
+}
+
 // Middleware global Middleware handle function
 func (r Route) Middleware(middleware func(gp *Goploy) error) Route {
 	r.middlewares = append(r.middlewares, middleware)
@@ -131,14 +183,30 @@ func (rt Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	_, resp := rt.doRequest(w, r)
-	if err := resp.Write(w); err != nil {
-		Log(ERROR, err.Error())
+	start := time.Now()
+	requestID := newRequestID()
+	w.Header().Set("X-Request-ID", requestID)
+	sw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+	gp, resp := rt.doRequest(sw, r, requestID)
+	writeErr := resp.Write(sw)
+
+	// writeErr is only an I/O failure flushing the response; a deny/validation
+	// failure writes its body just fine, so surface that through
+	// ResponseError instead of logging it as an error-less success.
+	logErr := writeErr
+	if logErr == nil {
+		if re, ok := resp.(ResponseError); ok {
+			if msg := re.ResponseError(); msg != "" {
+				logErr = errors.New(msg)
+			}
+		}
 	}
+	logRequest(gp, r, sw, requestID, time.Since(start), logErr)
 	return
 }
 
-func (rt Router) doRequest(w http.ResponseWriter, r *http.Request) (*Goploy, Response) {
+func (rt Router) doRequest(w http.ResponseWriter, r *http.Request, requestID string) (*Goploy, Response) {
 	route, ok := rt.routes[r.URL.Path]
 	if !ok {
 		return nil, response.JSON{Code: response.Deny, Message: "No such method"}
@@ -149,8 +217,19 @@ func (rt Router) doRequest(w http.ResponseWriter, r *http.Request) (*Goploy, Res
 
 	userInfo := model.User{}
 	namespace := model.Namespace{}
-	if !route.white {
-		// check token
+	bearerAuthenticated := false
+	if !route.white && bearerToken(r) != "" {
+		var err error
+		userInfo, namespace, err = authenticatePersonalAccessToken(r, route)
+		if err != nil {
+			return nil, response.JSON{Code: response.Deny, Message: err.Error()}
+		}
+		bearerAuthenticated = true
+	} else if !route.white {
+		// check token. This re-validates the session cookie on every
+		// request rather than just at login, so only its failure branches
+		// are audited below (as ActionLoginFailure) — auditing success here
+		// too would log one "login" per API call instead of per session.
 		goployTokenCookie, err := r.Cookie(config.Toml.Cookie.Name)
 		if err != nil {
 			return nil, response.JSON{Code: response.IllegalRequest, Message: "Illegal request"}
@@ -162,6 +241,7 @@ func (rt Router) doRequest(w http.ResponseWriter, r *http.Request) (*Goploy, Res
 		})
 
 		if err != nil || !token.Valid {
+			_ = audit.Record(requestID, 0, 0, audit.ActionLoginFailure, "cookie: invalid or expired token")
 			return nil, response.JSON{Code: response.LoginExpired, Message: "Login expired"}
 		}
 
@@ -181,14 +261,18 @@ func (rt Router) doRequest(w http.ResponseWriter, r *http.Request) (*Goploy, Res
 		}.GetDataByUserNamespace()
 
 		if err != nil {
+			userID := int64(claims["id"].(float64))
 			if err == sql.ErrNoRows {
+				_ = audit.Record(requestID, userID, namespaceID, audit.ActionLoginFailure, "cookie: no available namespace")
 				return nil, response.JSON{Code: response.NamespaceInvalid, Message: "No available namespace"}
 			} else {
+				_ = audit.Record(requestID, userID, namespaceID, audit.ActionLoginFailure, "cookie: "+err.Error())
 				return nil, response.JSON{Code: response.Deny, Message: err.Error()}
 			}
 		}
 
 		if err = route.hasRole(namespace.Role); err != nil {
+			_ = audit.Record(requestID, int64(claims["id"].(float64)), namespace.ID, audit.ActionLoginFailure, "cookie: "+err.Error())
 			return nil, response.JSON{Code: response.Deny, Message: err.Error()}
 		}
 
@@ -212,25 +296,35 @@ func (rt Router) doRequest(w http.ResponseWriter, r *http.Request) (*Goploy, Res
 		body, _ = ioutil.ReadAll(r.Body)
 	}
 	gp := &Goploy{
-		UserInfo:       userInfo,
-		Namespace:      namespace,
-		Request:        r,
-		ResponseWriter: w,
-		URLQuery:       r.URL.Query(),
-		Body:           body,
+		UserInfo:            userInfo,
+		Namespace:           namespace,
+		Request:             r,
+		ResponseWriter:      w,
+		URLQuery:            r.URL.Query(),
+		Body:                body,
+		RequestID:           requestID,
+		Context:             context.WithValue(r.Context(), requestIDContextKey{}, requestID),
+		White:               route.white,
+		CSRFExempt:          route.csrfExempt,
+		BearerAuthenticated: bearerAuthenticated,
 	}
 
 	// common middlewares
 	for _, middleware := range rt.middlewares {
 		err := middleware(gp)
-		if err != nil {
+		if err == ErrHandled {
+			return gp, noResponse{}
+		} else if err != nil {
 			return gp, response.JSON{Code: response.Error, Message: err.Error()}
 		}
 	}
 
 	// route middlewares
 	for _, middleware := range route.middlewares {
-		if err := middleware(gp); err != nil {
+		err := middleware(gp)
+		if err == ErrHandled {
+			return gp, noResponse{}
+		} else if err != nil {
 			return gp, response.JSON{Code: response.Error, Message: err.Error()}
 		}
 	}
@@ -249,6 +343,22 @@ func (r Route) hasRole(namespaceRole string) error {
 	return errors.New("no permission")
 }
 
+// hasScope checks a token's comma-separated scope list (as stored on
+// model.UserToken.Scopes) against the scopes the route declared via
+// Route.Scopes, passing if any one of them matches.
+func (r Route) hasScope(tokenScopes string) error {
+	if len(r.scopes) == 0 {
+		return nil
+	}
+
+	for _, scope := range strings.Split(tokenScopes, ",") {
+		if _, ok := r.scopes[strings.TrimSpace(scope)]; ok {
+			return nil
+		}
+	}
+	return errors.New("token scope does not permit this endpoint")
+}
+
 func hasContentType(r *http.Request, mimetype string) bool {
 	contentType := r.Header.Get("Content-type")
 	if contentType == "" {
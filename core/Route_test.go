@@ -0,0 +1,36 @@
+package core
+
+import "testing"
+
+func TestRouteHasScope(t *testing.T) {
+	route := NewRoute("/api/user/token/revoke", "POST", nil).Scopes("token:write")
+
+	cases := []struct {
+		name        string
+		tokenScopes string
+		wantErr     bool
+	}{
+		{"single matching scope", "token:write", false},
+		{"single non-matching scope", "token:read", true},
+		{"matching scope among several", "token:read,token:write", false},
+		{"matching scope with surrounding spaces", "token:read, token:write", false},
+		{"no matching scope among several", "token:read,repo:read", true},
+		{"empty scopes", "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := route.hasScope(c.tokenScopes)
+			if (err != nil) != c.wantErr {
+				t.Errorf("hasScope(%q) error = %v, wantErr %v", c.tokenScopes, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestRouteHasScopeUnrestricted(t *testing.T) {
+	route := NewRoute("/api/project/list", "GET", nil)
+	if err := route.hasScope(""); err != nil {
+		t.Errorf("hasScope on a route with no declared scopes should always pass, got %v", err)
+	}
+}
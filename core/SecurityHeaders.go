@@ -0,0 +1,26 @@
+package core
+
+import "github.com/zhenorzz/goploy/config"
+
+// SecurityHeaders sets response hardening headers, tunable through
+// config.Toml.Security. HSTS is only set in production since dev serves
+// over plain HTTP from npm (see the Env check already used by Start and
+// ServeHTTP).
+func SecurityHeaders(gp *Goploy) error {
+	cfg := config.Toml.Security
+	if !cfg.Enabled {
+		return nil
+	}
+
+	h := gp.ResponseWriter.Header()
+	if config.Toml.Env == "production" {
+		h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+	}
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("X-Frame-Options", "DENY")
+	h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	if cfg.CSP != "" {
+		h.Set("Content-Security-Policy", cfg.CSP)
+	}
+	return nil
+}
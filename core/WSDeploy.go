@@ -0,0 +1,53 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ProjectDeployTopic is the topic a project's live deploy output is
+// published to, and that its WSRoute/long-poll fallback subscribe to.
+func ProjectDeployTopic(projectID int64) string {
+	return "project:" + strconv.FormatInt(projectID, 10) + ":deploy"
+}
+
+// ServerExecTopic is the topic a server's live command output is
+// published to.
+func ServerExecTopic(serverID int64) string {
+	return "server:" + strconv.FormatInt(serverID, 10) + ":exec"
+}
+
+// PublishDeployLine fans one event out to any subscriber of
+// ProjectDeployTopic(projectID). StreamDeployLines calls it for each line it
+// reads, and middleware.HasPublishAuth calls it once with the "triggered"
+// stage when a publish is authorized; nothing else publishes deploy output.
+func PublishDeployLine(projectID int64, stage, stream, line string, exitCode *int) {
+	Hub.Publish(ProjectDeployTopic(projectID), WSEvent{
+		Stage:    stage,
+		Stream:   stream,
+		Line:     line,
+		Ts:       time.Now().Unix(),
+		ExitCode: exitCode,
+	})
+}
+
+// StreamDeployLines scans r (a running deploy step's stdout or stderr pipe)
+// line by line and calls PublishDeployLine for each one under the given
+// stage, so subscribers see output as the step actually produces it rather
+// than a single synthetic event. Intended to be run in its own goroutine
+// per pipe while the underlying command executes; returns once r is
+// exhausted or errors.
+//
+// No command-execution pipeline exists in this snapshot to call this from
+// yet — wiring it to the real per-server deploy runner (its stdout/stderr
+// pipes, and publishing the exit code via PublishDeployLine's exitCode
+// param once the command finishes) is follow-up work once that subsystem
+// lands.
+func StreamDeployLines(projectID int64, stage, stream string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		PublishDeployLine(projectID, stage, stream, scanner.Text(), nil)
+	}
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// WSEvent is a structured message published onto a topic, e.g.
+// "project:42:deploy" while a deployment streams its output.
+type WSEvent struct {
+	Stage    string `json:"stage"`
+	Stream   string `json:"stream"` // "stdout" | "stderr", empty for the "triggered" stage event
+	Line     string `json:"line"`
+	Ts       int64  `json:"ts"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+}
+
+// wsClientBacklog bounds how many unsent events a slow client may queue
+// before the hub drops it rather than blocking the broadcaster.
+const wsClientBacklog = 256
+
+type wsClient struct {
+	send   chan WSEvent
+	topics map[string]struct{}
+}
+
+type wsBroadcast struct {
+	topic string
+	event WSEvent
+}
+
+// WSHub is a goroutine-safe fan-out of WSEvents to subscribed clients,
+// shared by every WSRoute connection and by the deploy pipeline that
+// publishes into it.
+type WSHub struct {
+	mu         sync.RWMutex
+	clients    map[*wsClient]struct{}
+	byTopic    map[string]map[*wsClient]struct{}
+	register   chan *wsClient
+	unregister chan *wsClient
+	broadcast  chan wsBroadcast
+	shutdown   chan struct{}
+}
+
+// NewWSHub builds an unstarted hub; call Run in its own goroutine before
+// registering any client.
+func NewWSHub() *WSHub {
+	return &WSHub{
+		clients:    map[*wsClient]struct{}{},
+		byTopic:    map[string]map[*wsClient]struct{}{},
+		register:   make(chan *wsClient),
+		unregister: make(chan *wsClient),
+		broadcast:  make(chan wsBroadcast, wsClientBacklog),
+		shutdown:   make(chan struct{}),
+	}
+}
+
+// Hub is the process-wide WebSocket hub used by WSRoute connections and by
+// the deploy pipeline to publish live output.
+var Hub = NewWSHub()
+
+// Run processes register/unregister/broadcast events until Shutdown is
+// called. It is meant to run for the lifetime of the process in its own
+// goroutine, started alongside Router.Start.
+func (h *WSHub) Run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.mu.Lock()
+			h.clients[c] = struct{}{}
+			for topic := range c.topics {
+				if h.byTopic[topic] == nil {
+					h.byTopic[topic] = map[*wsClient]struct{}{}
+				}
+				h.byTopic[topic][c] = struct{}{}
+			}
+			h.mu.Unlock()
+		case c := <-h.unregister:
+			h.removeClient(c)
+		case b := <-h.broadcast:
+			h.mu.RLock()
+			for c := range h.byTopic[b.topic] {
+				select {
+				case c.send <- b.event:
+				default:
+					// Backlog full: drop this slow client instead of
+					// blocking every other subscriber on the same topic.
+					go func(c *wsClient) { h.unregister <- c }(c)
+				}
+			}
+			h.mu.RUnlock()
+		case <-h.shutdown:
+			h.closeAll()
+			return
+		}
+	}
+}
+
+func (h *WSHub) removeClient(c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	for topic := range c.topics {
+		delete(h.byTopic[topic], c)
+	}
+	close(c.send)
+}
+
+func (h *WSHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		close(c.send)
+	}
+	h.clients = map[*wsClient]struct{}{}
+	h.byTopic = map[string]map[*wsClient]struct{}{}
+}
+
+// Publish fans event out to every client subscribed to topic. If the hub's
+// broadcast queue is full it waits up to one second, then drops the event
+// and logs an error; a client whose backlog is full is dropped by Run.
+func (h *WSHub) Publish(topic string, event WSEvent) {
+	select {
+	case h.broadcast <- wsBroadcast{topic: topic, event: event}:
+	case <-time.After(time.Second):
+		Log(ERROR, "ws hub: broadcast channel full, dropping event for topic "+topic)
+	}
+}
+
+// Shutdown closes every connection with close code 1001 (going away) and
+// stops Run. Safe to call once during process shutdown.
+func (h *WSHub) Shutdown() {
+	close(h.shutdown)
+}
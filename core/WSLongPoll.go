@@ -0,0 +1,66 @@
+package core
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zhenorzz/goploy/response"
+)
+
+// wsLongPollTimeout bounds how long a long-poll request waits for an event
+// before returning an empty batch, so proxies that kill idle connections
+// don't see anything longer-lived than a normal request.
+const wsLongPollTimeout = 25 * time.Second
+
+// NewWSLongPollRoute builds a fallback for environments where the WebSocket
+// upgrade is proxied out: it subscribes to the same topic as the
+// equivalent WSRoute, waits for at least one event (or the timeout), and
+// returns whatever arrived as a JSON batch.
+func NewWSLongPollRoute(pattern string, topicFunc func(gp *Goploy) string) Route {
+	return NewRoute(pattern, http.MethodGet, func(gp *Goploy) Response {
+		client := &wsClient{
+			send:   make(chan WSEvent, wsClientBacklog),
+			topics: map[string]struct{}{topicFunc(gp): {}},
+		}
+		Hub.register <- client
+		defer func() { Hub.unregister <- client }()
+
+		events := make([]WSEvent, 0)
+		timeout := time.NewTimer(wsLongPollTimeout)
+		defer timeout.Stop()
+
+	collect:
+		for {
+			select {
+			case event, ok := <-client.send:
+				if !ok {
+					break collect
+				}
+				events = append(events, event)
+				break collect
+			case <-timeout.C:
+				break collect
+			}
+		}
+
+		// Drain any further events already queued, without blocking, so a
+		// burst of rapid log lines comes back as one batch instead of one
+		// long-poll round trip per line.
+	drain:
+		for len(events) < wsClientBacklog {
+			select {
+			case event, ok := <-client.send:
+				if !ok {
+					break drain
+				}
+				events = append(events, event)
+			default:
+				break drain
+			}
+		}
+
+		return response.JSON{Data: struct {
+			Events []WSEvent `json:"events"`
+		}{Events: events}}
+	})
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhenorzz/goploy/response"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPingPeriod = 30 * time.Second
+	wsPongWait   = wsPingPeriod * 3
+)
+
+var wsUpgrader = websocket.Upgrader{
+	// Namespace/role checks already ran in doRequest before this callback
+	// fires, so the upgrade itself doesn't need an extra origin check.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// NewWSRoute builds a Route that, once the usual JWT/namespace/role checks
+// in doRequest succeed, upgrades the connection and subscribes it to the
+// topic topicFunc derives from the request, e.g. "project:42:deploy".
+func NewWSRoute(pattern string, topicFunc func(gp *Goploy) string) Route {
+	return NewRoute(pattern, http.MethodGet, func(gp *Goploy) Response {
+		conn, err := wsUpgrader.Upgrade(gp.ResponseWriter, gp.Request, nil)
+		if err != nil {
+			return response.JSON{Code: response.Error, Message: err.Error()}
+		}
+
+		client := &wsClient{
+			send:   make(chan WSEvent, wsClientBacklog),
+			topics: map[string]struct{}{topicFunc(gp): {}},
+		}
+		Hub.register <- client
+
+		go wsWritePump(conn, client)
+		wsReadPump(conn, client)
+
+		return noResponse{}
+	})
+}
+
+// wsWritePump delivers published events and heartbeat pings to the client
+// until its send channel closes (on unregister) or the connection errors.
+func wsWritePump(conn *websocket.Conn, client *wsClient) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = conn.Close()
+	}()
+
+	for {
+		select {
+		case event, ok := <-client.send:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsReadPump only exists to notice the client going away: goploy's
+// WebSocket connections are server-to-client only, so inbound data frames
+// are discarded; only pong frames extend the read deadline.
+func wsReadPump(conn *websocket.Conn, client *wsClient) {
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	defer func() {
+		Hub.unregister <- client
+		_ = conn.Close()
+	}()
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// UserInfo is the normalized profile returned by every provider
+// regardless of how the upstream API shapes its response.
+type UserInfo struct {
+	Subject string // stable external id, unique per provider
+	Email   string
+	Name    string
+	Groups  []string // raw groups/claims used by RoleMapping
+}
+
+// Token is the result of exchanging an authorization code.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// Provider is implemented by every supported external identity provider.
+type Provider interface {
+	// Name returns the provider key used in the callback route, e.g. "github".
+	Name() string
+	// AuthCodeURL builds the redirect URL the browser is sent to, encoding state for CSRF protection.
+	AuthCodeURL(state string) string
+	// Exchange swaps an authorization code for an access token.
+	Exchange(code string) (*Token, error)
+	// FetchUserInfo loads the authenticated user's profile using the token from Exchange.
+	FetchUserInfo(token *Token) (*UserInfo, error)
+}
+
+var providers = map[string]Provider{}
+
+// Register adds a provider so it can be resolved by name from the callback route.
+func Register(p Provider) {
+	providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name, or an error if none matches.
+func Get(name string) (Provider, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, errors.New("oauth: unknown provider " + name)
+	}
+	return p, nil
+}
+
+// checkStatusOK rejects a non-200 upstream response before its body is
+// unmarshalled. Error responses (rate limiting, bad credentials, ...) are
+// often still valid JSON — e.g. GitHub's {"message":"Bad credentials"} on a
+// 401 — so without this check they'd decode cleanly into the expected
+// struct with every field left at its zero value instead of failing.
+func checkStatusOK(resp *http.Response, body []byte) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth: upstream returned %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zhenorzz/goploy/config"
+)
+
+// GithubProvider implements Provider for GitHub OAuth apps.
+type GithubProvider struct{}
+
+func (GithubProvider) Name() string {
+	return "github"
+}
+
+func (GithubProvider) AuthCodeURL(state string) string {
+	cfg := config.Toml.OAuth.Providers["github"]
+	v := url.Values{}
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("scope", strings.Join(cfg.Scopes, " "))
+	v.Set("state", state)
+	return "https://github.com/login/oauth/authorize?" + v.Encode()
+}
+
+func (GithubProvider) Exchange(code string) (*Token, error) {
+	cfg := config.Toml.OAuth.Providers["github"]
+	req, err := http.NewRequest(http.MethodPost, "https://github.com/login/oauth/access_token", strings.NewReader(url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {cfg.RedirectURL},
+	}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return nil, errors.New(data.Error)
+	}
+	return &Token{AccessToken: data.AccessToken}, nil
+}
+
+func (GithubProvider) FetchUserInfo(token *Token) (*UserInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token.AccessToken))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		Subject: fmt.Sprintf("github:%d", data.ID),
+		Email:   data.Email,
+		Name:    data.Login,
+	}, nil
+}
+
+func init() {
+	Register(GithubProvider{})
+}
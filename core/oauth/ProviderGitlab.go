@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zhenorzz/goploy/config"
+)
+
+// GitlabProvider implements Provider for GitLab OAuth applications,
+// including self-hosted instances via config.Toml.OAuth.Providers["gitlab"].BaseURL.
+type GitlabProvider struct{}
+
+func (GitlabProvider) Name() string {
+	return "gitlab"
+}
+
+func (p GitlabProvider) baseURL() string {
+	cfg := config.Toml.OAuth.Providers["gitlab"]
+	if cfg.BaseURL != "" {
+		return strings.TrimRight(cfg.BaseURL, "/")
+	}
+	return "https://gitlab.com"
+}
+
+func (p GitlabProvider) AuthCodeURL(state string) string {
+	cfg := config.Toml.OAuth.Providers["gitlab"]
+	v := url.Values{}
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("response_type", "code")
+	v.Set("scope", strings.Join(cfg.Scopes, " "))
+	v.Set("state", state)
+	return p.baseURL() + "/oauth/authorize?" + v.Encode()
+}
+
+func (p GitlabProvider) Exchange(code string) (*Token, error) {
+	cfg := config.Toml.OAuth.Providers["gitlab"]
+	req, err := http.NewRequest(http.MethodPost, p.baseURL()+"/oauth/token", strings.NewReader(url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {cfg.RedirectURL},
+	}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return nil, errors.New(data.Error)
+	}
+	return &Token{AccessToken: data.AccessToken}, nil
+}
+
+func (p GitlabProvider) FetchUserInfo(token *Token) (*UserInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, p.baseURL()+"/api/v4/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		Subject: fmt.Sprintf("gitlab:%d", data.ID),
+		Email:   data.Email,
+		Name:    data.Username,
+	}, nil
+}
+
+func init() {
+	Register(GitlabProvider{})
+}
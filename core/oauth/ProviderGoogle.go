@@ -0,0 +1,102 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zhenorzz/goploy/config"
+)
+
+// GoogleProvider implements Provider for Google OAuth/OIDC.
+type GoogleProvider struct{}
+
+func (GoogleProvider) Name() string {
+	return "google"
+}
+
+func (GoogleProvider) AuthCodeURL(state string) string {
+	cfg := config.Toml.OAuth.Providers["google"]
+	v := url.Values{}
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("response_type", "code")
+	v.Set("scope", strings.Join(cfg.Scopes, " "))
+	v.Set("state", state)
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + v.Encode()
+}
+
+func (GoogleProvider) Exchange(code string) (*Token, error) {
+	cfg := config.Toml.OAuth.Providers["google"]
+	resp, err := http.PostForm("https://oauth2.googleapis.com/token", url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {cfg.RedirectURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return nil, errors.New(data.Error)
+	}
+	return &Token{AccessToken: data.AccessToken}, nil
+}
+
+func (GoogleProvider) FetchUserInfo(token *Token) (*UserInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://www.googleapis.com/oauth2/v3/userinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Sub    string `json:"sub"`
+		Email  string `json:"email"`
+		Name   string `json:"name"`
+		Domain string `json:"hd"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		Subject: "google:" + data.Sub,
+		Email:   data.Email,
+		Name:    data.Name,
+	}, nil
+}
+
+func init() {
+	Register(GoogleProvider{})
+}
@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zhenorzz/goploy/config"
+)
+
+// OIDCProvider implements Provider against any generic OpenID Connect
+// discovery-compatible issuer configured under config.Toml.OAuth.Providers["oidc"].
+type OIDCProvider struct{}
+
+func (OIDCProvider) Name() string {
+	return "oidc"
+}
+
+func (OIDCProvider) AuthCodeURL(state string) string {
+	cfg := config.Toml.OAuth.Providers["oidc"]
+	v := url.Values{}
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("response_type", "code")
+	v.Set("scope", strings.Join(cfg.Scopes, " "))
+	v.Set("state", state)
+	return strings.TrimRight(cfg.AuthURL, "/") + "?" + v.Encode()
+}
+
+func (OIDCProvider) Exchange(code string) (*Token, error) {
+	cfg := config.Toml.OAuth.Providers["oidc"]
+	resp, err := http.PostForm(cfg.TokenURL, url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {cfg.RedirectURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return nil, errors.New(data.Error)
+	}
+	return &Token{AccessToken: data.AccessToken}, nil
+}
+
+func (OIDCProvider) FetchUserInfo(token *Token) (*UserInfo, error) {
+	cfg := config.Toml.OAuth.Providers["oidc"]
+	req, err := http.NewRequest(http.MethodGet, cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusOK(resp, body); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Sub    string   `json:"sub"`
+		Email  string   `json:"email"`
+		Name   string   `json:"name"`
+		Groups []string `json:"groups"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		Subject: "oidc:" + data.Sub,
+		Email:   data.Email,
+		Name:    data.Name,
+		Groups:  data.Groups,
+	}, nil
+}
+
+func init() {
+	Register(OIDCProvider{})
+}
@@ -0,0 +1,41 @@
+package oauth
+
+import "github.com/zhenorzz/goploy/config"
+
+// MapRole translates the groups/claims an identity provider reports for a
+// user into one of this instance's namespace roles, falling back to the
+// configured default role when nothing matches.
+func MapRole(groups []string) string {
+	for _, group := range groups {
+		if role, ok := config.Toml.OAuth.RoleMapping[group]; ok {
+			return role
+		}
+	}
+	return config.Toml.OAuth.DefaultRole
+}
+
+// allowedDomain reports whether email belongs to one of the configured
+// allowed domains. An empty allow-list permits every domain.
+func allowedDomain(email string) bool {
+	allowed := config.Toml.OAuth.AllowedDomains
+	if len(allowed) == 0 {
+		return true
+	}
+	at := -1
+	for i := len(email) - 1; i >= 0; i-- {
+		if email[i] == '@' {
+			at = i
+			break
+		}
+	}
+	if at == -1 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range allowed {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/zhenorzz/goploy/audit"
+	"github.com/zhenorzz/goploy/config"
+	"github.com/zhenorzz/goploy/core"
+	"github.com/zhenorzz/goploy/model"
+	"github.com/zhenorzz/goploy/response"
+)
+
+const stateCookieName = "goploy_oauth_state"
+
+// OAuth implements core.RouteApi, exposing the login and callback
+// endpoints shared by every configured identity provider.
+type OAuth struct{}
+
+// loginRateLimit is tighter than the router's global default: unlike most
+// endpoints, /api/oauth/login is a pre-auth entry point an attacker can
+// hammer to brute-force state tokens or exhaust a provider's quota.
+var loginRateLimit = core.RateLimit(core.RateLimitOptions{Rate: 0.2, Burst: 5})
+
+func (OAuth) Routes() []core.Route {
+	return []core.Route{
+		core.NewRoute("/api/oauth/login", http.MethodGet, login).White().Middleware(loginRateLimit),
+		core.NewRoute("/api/oauth/callback", http.MethodGet, callback).White().Middleware(loginRateLimit),
+	}
+}
+
+func login(gp *core.Goploy) core.Response {
+	providerName := gp.URLQuery.Get("provider")
+	p, err := Get(providerName)
+	if err != nil {
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	state := randomState()
+	http.SetCookie(gp.ResponseWriter, &http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   300,
+		HttpOnly: true,
+	})
+
+	http.Redirect(gp.ResponseWriter, gp.Request, p.AuthCodeURL(state), http.StatusFound)
+	return response.JSON{}
+}
+
+func callback(gp *core.Goploy) core.Response {
+	providerName := gp.URLQuery.Get("provider")
+	p, err := Get(providerName)
+	if err != nil {
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	stateCookie, err := gp.Request.Cookie(stateCookieName)
+	if err != nil || stateCookie.Value == "" || stateCookie.Value != gp.URLQuery.Get("state") {
+		_ = audit.Record(gp.RequestID, 0, 0, audit.ActionLoginFailure, providerName+": invalid oauth state")
+		return response.JSON{Code: response.IllegalRequest, Message: "Invalid oauth state"}
+	}
+
+	token, err := p.Exchange(gp.URLQuery.Get("code"))
+	if err != nil {
+		_ = audit.Record(gp.RequestID, 0, 0, audit.ActionLoginFailure, providerName+": "+err.Error())
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	userInfo, err := p.FetchUserInfo(token)
+	if err != nil {
+		_ = audit.Record(gp.RequestID, 0, 0, audit.ActionLoginFailure, providerName+": "+err.Error())
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	if !allowedDomain(userInfo.Email) {
+		_ = audit.Record(gp.RequestID, 0, 0, audit.ActionLoginFailure, providerName+": email domain not allowed")
+		return response.JSON{Code: response.Deny, Message: "Email domain is not allowed to sign in"}
+	}
+
+	user, err := provisionOrLinkUser(providerName, userInfo)
+	if err != nil {
+		_ = audit.Record(gp.RequestID, 0, 0, audit.ActionLoginFailure, providerName+": "+err.Error())
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	goployTokenStr, err := model.User{ID: user.ID, Name: user.Name}.CreateToken()
+	if err != nil {
+		_ = audit.Record(gp.RequestID, user.ID, 0, audit.ActionLoginFailure, providerName+": "+err.Error())
+		return response.JSON{Code: response.Deny, Message: err.Error()}
+	}
+
+	_ = audit.Record(gp.RequestID, user.ID, 0, audit.ActionLoginSuccess, "oauth:"+providerName)
+
+	http.SetCookie(gp.ResponseWriter, &http.Cookie{
+		Name:     config.Toml.Cookie.Name,
+		Value:    goployTokenStr,
+		Path:     "/",
+		MaxAge:   config.Toml.Cookie.Expire,
+		HttpOnly: true,
+	})
+
+	http.Redirect(gp.ResponseWriter, gp.Request, "/", http.StatusFound)
+	return response.JSON{}
+}
+
+// provisionOrLinkUser resolves the SSO subject to a local account, creating
+// one on first login and assigning the role MapRole derives from the
+// provider's reported groups/claims.
+func provisionOrLinkUser(providerName string, info *UserInfo) (model.User, error) {
+	user, err := model.User{}.GetDataByOAuthSubject(providerName, info.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if err != sql.ErrNoRows {
+		return model.User{}, err
+	}
+
+	role := MapRole(info.Groups)
+	return model.User{
+		Name:  info.Name,
+		Email: info.Email,
+		Role:  role,
+	}.CreateByOAuth(providerName, info.Subject)
+}
+
+func randomState() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
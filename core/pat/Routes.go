@@ -0,0 +1,111 @@
+// Package pat exposes CRUD routes for Personal Access Tokens, the
+// non-browser counterpart to the cookie/JWT login used by CI runners,
+// curl scripts, and webhook senders.
+package pat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/zhenorzz/goploy/audit"
+	"github.com/zhenorzz/goploy/core"
+	"github.com/zhenorzz/goploy/model"
+	"github.com/zhenorzz/goploy/response"
+)
+
+// UserToken implements core.RouteApi for the Personal Access Token CRUD endpoints.
+type UserToken struct{}
+
+func (UserToken) Routes() []core.Route {
+	return []core.Route{
+		core.NewRoute("/api/user/token/list", http.MethodGet, list).Scopes("token:read"),
+		core.NewRoute("/api/user/token/create", http.MethodPost, create).Scopes("token:write"),
+		core.NewRoute("/api/user/token/revoke", http.MethodPut, revoke).Scopes("token:write"),
+	}
+}
+
+func list(gp *core.Goploy) core.Response {
+	tokens, err := model.UserToken{UserID: gp.UserInfo.ID}.GetListByUserID()
+	if err != nil {
+		return response.JSON{Code: response.Error, Message: err.Error()}
+	}
+	return response.JSON{Data: struct {
+		Tokens model.UserTokens `json:"list"`
+	}{Tokens: tokens}}
+}
+
+func create(gp *core.Goploy) core.Response {
+	type ReqData struct {
+		Name      string   `json:"name"`
+		Scopes    []string `json:"scopes"`
+		ExpiresAt int64    `json:"expiresAt"`
+	}
+	var reqData ReqData
+	if err := json.Unmarshal(gp.Body, &reqData); err != nil {
+		return response.JSON{Code: response.IllegalRequest, Message: err.Error()}
+	}
+
+	rawToken, err := generateToken()
+	if err != nil {
+		return response.JSON{Code: response.Error, Message: err.Error()}
+	}
+
+	id, err := model.UserToken{
+		UserID:    gp.UserInfo.ID,
+		Name:      reqData.Name,
+		Hash:      core.HashPersonalAccessToken(rawToken),
+		Scopes:    joinScopes(reqData.Scopes),
+		ExpiresAt: reqData.ExpiresAt,
+	}.AddRow()
+	if err != nil {
+		return response.JSON{Code: response.Error, Message: err.Error()}
+	}
+
+	_ = audit.Record(gp.RequestID, gp.UserInfo.ID, gp.Namespace.ID, audit.ActionTokenIssued, reqData.Name)
+
+	// rawToken is only ever shown this once; only the hash is persisted.
+	return response.JSON{Data: struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}{ID: id, Token: rawToken}}
+}
+
+func revoke(gp *core.Goploy) core.Response {
+	type ReqData struct {
+		ID int64 `json:"id"`
+	}
+	var reqData ReqData
+	if err := json.Unmarshal(gp.Body, &reqData); err != nil {
+		return response.JSON{Code: response.IllegalRequest, Message: err.Error()}
+	}
+
+	if err := (model.UserToken{ID: reqData.ID, UserID: gp.UserInfo.ID}).Revoke(); err != nil {
+		return response.JSON{Code: response.Error, Message: err.Error()}
+	}
+
+	_ = audit.Record(gp.RequestID, gp.UserInfo.ID, gp.Namespace.ID, audit.ActionTokenRevoked, strconv.FormatInt(reqData.ID, 10))
+
+	return response.JSON{}
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "gpat_" + hex.EncodeToString(b), nil
+}
+
+func joinScopes(scopes []string) string {
+	out := ""
+	for i, s := range scopes {
+		if i > 0 {
+			out += ","
+		}
+		out += s
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+// Package ws registers the WebSocket (and long-poll fallback) routes that
+// let the frontend subscribe to a project's live deploy output (published
+// via core.PublishDeployLine and core.Hub) or to a server's command output
+// topic (core.ServerExecTopic), which nothing in this codebase publishes to.
+package ws
+
+import (
+	"strconv"
+
+	"github.com/zhenorzz/goploy/core"
+)
+
+// Log implements core.RouteApi for the deploy/server log streaming endpoints.
+type Log struct{}
+
+func (Log) Routes() []core.Route {
+	return []core.Route{
+		core.NewWSRoute("/api/ws/project/log", projectDeployTopic),
+		core.NewWSLongPollRoute("/api/ws/project/log/poll", projectDeployTopic),
+		core.NewWSRoute("/api/ws/server/log", serverExecTopic),
+		core.NewWSLongPollRoute("/api/ws/server/log/poll", serverExecTopic),
+	}
+}
+
+// projectDeployTopic resolves the ?projectId= query param to the topic
+// core.PublishDeployLine publishes a project's deploy output to.
+func projectDeployTopic(gp *core.Goploy) string {
+	projectID, _ := strconv.ParseInt(gp.URLQuery.Get("projectId"), 10, 64)
+	return core.ProjectDeployTopic(projectID)
+}
+
+// serverExecTopic resolves the ?serverId= query param to the topic a
+// server's live command output is published to.
+func serverExecTopic(gp *core.Goploy) string {
+	serverID, _ := strconv.ParseInt(gp.URLQuery.Get("serverId"), 10, 64)
+	return core.ServerExecTopic(serverID)
+}
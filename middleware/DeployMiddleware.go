@@ -3,6 +3,9 @@ package middleware
 import (
 	"encoding/json"
 	"errors"
+	"strconv"
+
+	"github.com/zhenorzz/goploy/audit"
 	"github.com/zhenorzz/goploy/core"
 	"github.com/zhenorzz/goploy/model"
 )
@@ -21,6 +24,13 @@ func HasPublishAuth(gp *core.Goploy) error {
 	if err != nil {
 		return errors.New("no permission")
 	}
+
+	// Give subscribers of core.ProjectDeployTopic an immediate signal that a
+	// deploy was triggered. The actual per-line stdout/stderr streaming
+	// (core.StreamDeployLines, fed from the deploy runner's command pipes)
+	// has no command-execution pipeline to hook into in this snapshot yet.
+	core.PublishDeployLine(reqData.ProjectID, "triggered", "", "", nil)
+	_ = audit.Record(gp.RequestID, gp.UserInfo.ID, gp.Namespace.ID, audit.ActionPublishTriggered, strconv.FormatInt(reqData.ProjectID, 10))
 	return nil
 }
 
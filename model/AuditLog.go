@@ -0,0 +1,55 @@
+package model
+
+// AuditLog is one row in the audit_log table: a security-relevant event
+// correlated back to the request (core.Goploy.RequestID) that produced it.
+type AuditLog struct {
+	ID          int64  `json:"id"`
+	RequestID   string `json:"requestId"`
+	UserID      int64  `json:"userId"`
+	NamespaceID int64  `json:"namespaceId"`
+	Action      string `json:"action"`
+	Detail      string `json:"detail"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+type AuditLogs []AuditLog
+
+// AddRow inserts a new audit event and returns its id.
+func (a AuditLog) AddRow() (int64, error) {
+	result, err := DB.Exec(
+		"INSERT INTO `audit_log` (request_id, user_id, namespace_id, action, detail, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		a.RequestID, a.UserID, a.NamespaceID, a.Action, a.Detail, a.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetListByPage returns a page of audit events, most recent first, along
+// with the total row count for pagination.
+func (a AuditLog) GetListByPage(page, limit int64) (AuditLogs, int64, error) {
+	var total int64
+	if err := DB.QueryRow("SELECT COUNT(*) FROM `audit_log`").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT id, request_id, user_id, namespace_id, action, detail, created_at FROM `audit_log` "+
+			"ORDER BY id DESC LIMIT ? OFFSET ?", limit, (page-1)*limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := AuditLogs{}
+	for rows.Next() {
+		var l AuditLog
+		if err := rows.Scan(&l.ID, &l.RequestID, &l.UserID, &l.NamespaceID, &l.Action, &l.Detail, &l.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, total, rows.Err()
+}
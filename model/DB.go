@@ -0,0 +1,6 @@
+package model
+
+import "database/sql"
+
+// DB is the shared database handle, initialized during application startup.
+var DB *sql.DB
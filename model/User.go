@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/zhenorzz/goploy/config"
+)
+
+// User is a row in the user table; core.Route's login path reads and
+// re-signs it on every authenticated request.
+type User struct {
+	ID      int64  `json:"id"`
+	Account string `json:"account"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Role    string `json:"role"` // default namespace role assigned on first-time SSO provisioning
+}
+
+// GetData returns the user identified by ID.
+func (u User) GetData() (User, error) {
+	var user User
+	err := DB.QueryRow(
+		"SELECT id, account, name, email FROM `user` WHERE id = ?", u.ID,
+	).Scan(&user.ID, &user.Account, &user.Name, &user.Email)
+	return user, err
+}
+
+// CreateToken signs the JWT stored in the goploy cookie.
+func (u User) CreateToken() (string, error) {
+	claims := jwt.MapClaims{
+		"id":   u.ID,
+		"name": u.Name,
+		"exp":  time.Now().Add(time.Duration(config.Toml.Cookie.Expire) * time.Second).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.Toml.JWT.Key))
+}
+
+// GetDataByOAuthSubject resolves a previously linked SSO identity
+// (provider + external subject, see user_oauth) back to the local account.
+func (u User) GetDataByOAuthSubject(provider, subject string) (User, error) {
+	var user User
+	err := DB.QueryRow(
+		"SELECT u.id, u.account, u.name, u.email FROM `user` u "+
+			"INNER JOIN `user_oauth` o ON o.user_id = u.id "+
+			"WHERE o.provider = ? AND o.subject = ?", provider, subject,
+	).Scan(&user.ID, &user.Account, &user.Name, &user.Email)
+	return user, err
+}
+
+// CreateByOAuth provisions a new account on a first-time SSO login and
+// links it to provider/subject so later logins resolve via
+// GetDataByOAuthSubject.
+func (u User) CreateByOAuth(provider, subject string) (User, error) {
+	result, err := DB.Exec(
+		"INSERT INTO `user` (account, name, email, role) VALUES (?, ?, ?, ?)",
+		u.Email, u.Name, u.Email, u.Role,
+	)
+	if err != nil {
+		return User{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return User{}, err
+	}
+
+	if _, err := DB.Exec(
+		"INSERT INTO `user_oauth` (user_id, provider, subject) VALUES (?, ?, ?)", id, provider, subject,
+	); err != nil {
+		return User{}, err
+	}
+
+	u.ID = id
+	return u, nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// UserToken is a Personal Access Token issued to a user for non-browser
+// API and webhook clients. Only Hash is ever persisted; the raw gpat_...
+// value is shown once at creation and never stored.
+type UserToken struct {
+	ID         int64  `json:"id"`
+	UserID     int64  `json:"userId"`
+	Name       string `json:"name"`
+	Hash       string `json:"-"`
+	Scopes     string `json:"scopes"` // comma-separated scope names, see core.Route.Scopes
+	LastUsedAt int64  `json:"lastUsedAt"`
+	ExpiresAt  int64  `json:"expiresAt"`
+	RevokedAt  int64  `json:"revokedAt"`
+	CreatedAt  int64  `json:"createdAt"`
+}
+
+type UserTokens []UserToken
+
+// GetDataByHash looks up a token by its salted SHA-256 hash.
+func (ut UserToken) GetDataByHash(hash string) (UserToken, error) {
+	var token UserToken
+	err := DB.QueryRow(
+		"SELECT id, user_id, name, hash, scopes, last_used_at, expires_at, revoked_at, created_at "+
+			"FROM `user_token` WHERE hash = ?", hash,
+	).Scan(&token.ID, &token.UserID, &token.Name, &token.Hash, &token.Scopes, &token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt)
+	return token, err
+}
+
+// AddRow inserts a new Personal Access Token and returns its id.
+func (ut UserToken) AddRow() (int64, error) {
+	result, err := DB.Exec(
+		"INSERT INTO `user_token` (user_id, name, hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?)",
+		ut.UserID, ut.Name, ut.Hash, ut.Scopes, ut.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetListByUserID returns every token (revoked or not) owned by ut.UserID.
+func (ut UserToken) GetListByUserID() (UserTokens, error) {
+	rows, err := DB.Query(
+		"SELECT id, user_id, name, hash, scopes, last_used_at, expires_at, revoked_at, created_at "+
+			"FROM `user_token` WHERE user_id = ? ORDER BY id DESC", ut.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := UserTokens{}
+	for rows.Next() {
+		var token UserToken
+		if err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.Hash, &token.Scopes, &token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// UpdateLastUsedAt stamps the token as having just authenticated a request.
+func (ut UserToken) UpdateLastUsedAt() error {
+	_, err := DB.Exec("UPDATE `user_token` SET last_used_at = ? WHERE id = ?", time.Now().Unix(), ut.ID)
+	return err
+}
+
+// Revoke marks the token as revoked without deleting its row, preserving
+// the audit trail of what it was used for.
+func (ut UserToken) Revoke() error {
+	_, err := DB.Exec("UPDATE `user_token` SET revoked_at = ? WHERE id = ? AND user_id = ?", time.Now().Unix(), ut.ID, ut.UserID)
+	return err
+}